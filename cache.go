@@ -1,17 +1,23 @@
 package cache
 
 import (
-	"errors"
-	"fmt"
+	"container/list"
 	"log"
 	"reflect"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"golang.org/x/sync/singleflight"
 )
 
 const DefaultMemory = 100 * 1024 * 1024
 
+// DefaultJanitorInterval is how often the background janitor sweeps for
+// expired entries when NewSowhyCache is called without WithJanitorInterval.
+const DefaultJanitorInterval = 10 * time.Second
+
 type Cache interface {
 	// size 是 个字符 。 持以下参数: 1KB，100KB，1MB，2MB，1GB 等
 	SetMaxMemory(size string) bool
@@ -28,31 +34,276 @@ type Cache interface {
 	Keys() int64
 }
 
+// EvictionPolicy controls what SowhyCache does when a Set would push
+// CurMemory past Memory.
+type EvictionPolicy int
+
+const (
+	// EvictReject rejects the write and leaves the cache unchanged. This
+	// is the original, default behavior.
+	EvictReject EvictionPolicy = iota
+	// EvictLRU evicts the least-recently-used entry (by Get/Set access)
+	// until there is room.
+	EvictLRU
+	// EvictLFU evicts the least-frequently-used entry (by Get count)
+	// until there is room.
+	EvictLFU
+)
+
 type Value struct {
-	Value      interface{}
-	ExpireTime *time.Timer
+	Value interface{}
+	// Expiration is the absolute time (nanoseconds since epoch) at which
+	// this entry expires. 0 means the entry never expires.
+	Expiration int64
+	// Size is the byte size computed at Set time, cached here so LRU/LFU
+	// eviction accounting is O(1) instead of re-running sizeof.
+	Size int64
+	// freq counts Get accesses; only consulted under EvictLFU.
+	freq int64
+
+	// Sliding marks an entry set via SetSliding: instead of a fixed
+	// Expiration, it expires Idle after its LastAccess, renewed on every
+	// Get or Touch.
+	Sliding    bool
+	Idle       time.Duration
+	LastAccess int64 // nanoseconds since epoch
+}
+
+// Expired reports whether v is expired as of now (nanoseconds since epoch).
+func (v *Value) Expired(now int64) bool {
+	if v.Sliding {
+		if v.Idle <= 0 {
+			return false
+		}
+		return now > v.LastAccess+int64(v.Idle)
+	}
+	if v.Expiration == 0 {
+		return false
+	}
+	return now > v.Expiration
+}
+
+// lruEntry is the payload stored in each list.Element so an evicted
+// element can be removed from both the list and Iterm.
+type lruEntry struct {
+	key string
+	val *Value
+}
+
+// evictedItem records a key/value pair removed from the cache so its
+// OnEvicted callback can be fired after the lock protecting it is released.
+type evictedItem struct {
+	key string
+	val interface{}
 }
 
 type SowhyCache struct {
 	Lock      sync.RWMutex
-	Iterm     map[string]*Value
+	Iterm     map[string]*list.Element
+	ll        *list.List
 	Memory    int64
 	CurMemory int64
+
+	policy    EvictionPolicy
+	onEvicted func(key string, val interface{})
+	janitor   *janitor
+	closeOnce sync.Once
+
+	hits      int64
+	misses    int64
+	evictions int64
+
+	sf singleflight.Group
+}
+
+// Stats is a point-in-time snapshot returned by SowhyCache.Stats.
+type Stats struct {
+	Hits      int64
+	Misses    int64
+	Evictions int64
+	Bytes     int64
 }
 
-func NewSowhyCache() *SowhyCache {
-	return &SowhyCache{
+// Option configures a SowhyCache at construction time.
+type Option func(*cacheConfig)
+
+type cacheConfig struct {
+	janitorInterval time.Duration
+}
+
+// WithJanitorInterval overrides how often the background janitor sweeps
+// for expired entries. The default is DefaultJanitorInterval.
+func WithJanitorInterval(d time.Duration) Option {
+	return func(cfg *cacheConfig) {
+		cfg.janitorInterval = d
+	}
+}
+
+func NewSowhyCache(opts ...Option) *SowhyCache {
+	cfg := &cacheConfig{janitorInterval: DefaultJanitorInterval}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	c := &SowhyCache{
 		Lock:      sync.RWMutex{},
-		Iterm:     make(map[string]*Value),
+		Iterm:     make(map[string]*list.Element),
+		ll:        list.New(),
 		Memory:    DefaultMemory,
 		CurMemory: 0,
+		policy:    EvictReject,
+	}
+
+	j := &janitor{
+		interval: cfg.janitorInterval,
+		stop:     make(chan struct{}),
+	}
+	c.janitor = j
+	go j.run(c)
+
+	return c
+}
+
+// SetOnEvicted registers a callback invoked whenever an entry is removed,
+// whether by Del, by the background janitor, or by LRU/LFU eviction.
+// Flush does not invoke it. Pass nil to unregister.
+func (c *SowhyCache) SetOnEvicted(f func(key string, val interface{})) {
+	c.Lock.Lock()
+	c.onEvicted = f
+	c.Lock.Unlock()
+}
+
+// SetEvictionPolicy chooses what Set does when a write would exceed Memory.
+func (c *SowhyCache) SetEvictionPolicy(p EvictionPolicy) {
+	c.Lock.Lock()
+	c.policy = p
+	c.Lock.Unlock()
+}
+
+// Stats returns a snapshot of cache hit/miss/eviction counters and the
+// current byte count, useful for tuning Memory and the eviction policy.
+func (c *SowhyCache) Stats() Stats {
+	c.Lock.RLock()
+	bytes := c.CurMemory
+	c.Lock.RUnlock()
+
+	return Stats{
+		Hits:      atomic.LoadInt64(&c.hits),
+		Misses:    atomic.LoadInt64(&c.misses),
+		Evictions: atomic.LoadInt64(&c.evictions),
+		Bytes:     bytes,
+	}
+}
+
+// Close stops the background janitor. It is safe to call multiple times.
+func (c *SowhyCache) Close() {
+	c.closeOnce.Do(func() {
+		close(c.janitor.stop)
+	})
+}
+
+// janitor periodically sweeps a SowhyCache for expired entries until stop
+// is closed. This replaces the old per-key timer-goroutine model, which
+// leaked a goroutine on every Set and never freed it on Del.
+type janitor struct {
+	interval time.Duration
+	stop     chan struct{}
+}
+
+func (j *janitor) run(c *SowhyCache) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.deleteExpired()
+		case <-j.stop:
+			return
+		}
+	}
+}
+
+func (c *SowhyCache) deleteExpired() {
+	now := time.Now().UnixNano()
+
+	c.Lock.Lock()
+	var evicted []evictedItem
+	for e := c.ll.Front(); e != nil; {
+		next := e.Next()
+		entry := e.Value.(*lruEntry)
+		if entry.val.Expired(now) {
+			key, val := c.removeElement(e, true)
+			evicted = append(evicted, evictedItem{key, val.Value})
+		}
+		e = next
+	}
+	onEvicted := c.onEvicted
+	c.Lock.Unlock()
+
+	if onEvicted != nil {
+		for _, it := range evicted {
+			onEvicted(it.key, it.val)
+		}
 	}
 }
 
+// removeElement unlinks elem from both c.ll and c.Iterm and adjusts
+// CurMemory. Callers must hold c.Lock. If asEviction is true the eviction
+// counter is bumped; explicit Del calls pass false.
+func (c *SowhyCache) removeElement(elem *list.Element, asEviction bool) (string, *Value) {
+	entry := c.ll.Remove(elem).(*lruEntry)
+	delete(c.Iterm, entry.key)
+	c.CurMemory -= entry.val.Size
+	if asEviction {
+		atomic.AddInt64(&c.evictions, 1)
+	}
+	return entry.key, entry.val
+}
+
+// evictOne removes one entry under the active policy to make room for a
+// new write. Callers must hold c.Lock.
+func (c *SowhyCache) evictOne() (string, *Value) {
+	elem := c.ll.Back()
+	if c.policy == EvictLFU {
+		if lfu := c.leastFrequent(); lfu != nil {
+			elem = lfu
+		}
+	}
+	return c.removeElement(elem, true)
+}
+
+func (c *SowhyCache) leastFrequent() *list.Element {
+	var min *list.Element
+	for e := c.ll.Front(); e != nil; e = e.Next() {
+		if min == nil || e.Value.(*lruEntry).val.freq < min.Value.(*lruEntry).val.freq {
+			min = e
+		}
+	}
+	return min
+}
+
 func (c *SowhyCache) SetMaxMemory(size string) bool {
+	bytes, err := parseMemorySize(size)
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+
+	// 当重设MaxMemory的时候，检查设置是否满足当前Cache大小
+	if c.CurMemory >= bytes {
+		log.Println("can't reset MaxMemory!")
+	}
+
+	c.Memory = bytes
+	return true
+}
+
+// parseMemorySize parses a "<n><unit>" string (KB/MB/GB, case-insensitive,
+// or any other one-character suffix such as "B", meaning bytes) into a byte
+// count. A string without a unit loses its last digit to that suffix rule. Shared by SetMaxMemory and
+// ShardedCache, which divides the parsed total evenly across its shards.
+func parseMemorySize(size string) (int64, error) {
 	var s int64
-	var si int
-	var err error
 	switch size[len(size)-2:] {
 	case "KB", "kb", "kB", "Kb":
 		s = 1024
@@ -64,23 +315,17 @@ func (c *SowhyCache) SetMaxMemory(size string) bool {
 		s = 1
 	}
 
+	var si int
+	var err error
 	if s != 1 {
 		si, err = getMax(size[:len(size)-2])
 	} else {
 		si, err = getMax(size[:len(size)-1])
 	}
 	if err != nil {
-		log.Println(err)
-		return false
+		return 0, err
 	}
-
-	// 当重设MaxMemory的时候，检查设置是否满足当前Cache大小
-	if c.CurMemory >= s*int64(si) {
-		log.Println("can't reset MaxMemory!")
-	}
-
-	c.Memory = s * int64(si)
-	return true
+	return s * int64(si), nil
 }
 
 func getMax(size string) (int, error) {
@@ -89,83 +334,247 @@ func getMax(size string) (int, error) {
 
 func (c *SowhyCache) Set(key string, val interface{}, d time.Duration) {
 	c.Lock.Lock()
-	defer c.Lock.Unlock()
+	evicted := c.setLocked(key, val, func(size int64) *Value {
+		v := &Value{Value: val, Size: size}
+		// 判断是否有过期时间
+		if d > 0 {
+			v.Expiration = time.Now().Add(d).UnixNano()
+		}
+		return v
+	})
+	onEvicted := c.onEvicted
+	c.Lock.Unlock()
+
+	if onEvicted != nil {
+		for _, e := range evicted {
+			onEvicted(e.key, e.val)
+		}
+	}
+}
 
-	// 检查是否超出了MaxMemory
-	size := sizeof(reflect.ValueOf(val))
-	if (c.Memory - c.CurMemory) < int64(size) {
-		log.Println("cache is full,this val can't be saved", ", val: ", val)
-		return
+// SetSliding stores val under key with a sliding (last-access) expiration:
+// instead of expiring idle after a fixed point in time, it expires idle
+// after whichever Get or Touch call happened most recently.
+func (c *SowhyCache) SetSliding(key string, val interface{}, idle time.Duration) {
+	c.Lock.Lock()
+	evicted := c.setLocked(key, val, func(size int64) *Value {
+		return &Value{
+			Value:      val,
+			Size:       size,
+			Sliding:    true,
+			Idle:       idle,
+			LastAccess: time.Now().UnixNano(),
+		}
+	})
+	onEvicted := c.onEvicted
+	c.Lock.Unlock()
+
+	if onEvicted != nil {
+		for _, e := range evicted {
+			onEvicted(e.key, e.val)
+		}
 	}
+}
 
-	if _, found := c.Iterm[key]; found {
-		// 当key重复设置的时候
-		log.Printf("this key %s is already existed!\n", key)
-		return
+// setLocked performs the body of Set/SetSliding and returns whatever had
+// to be evicted to make room. build constructs the Value to store once
+// size and room for it are known. Callers must hold c.Lock and are
+// responsible for firing onEvicted themselves once unlocked; this lets
+// SetMulti batch many keys under a single lock/unlock pair per shard.
+func (c *SowhyCache) setLocked(key string, val interface{}, build func(size int64) *Value) []evictedItem {
+	size := int64(sizeof(reflect.ValueOf(val)))
+
+	var evicted []evictedItem
+	if elem, found := c.Iterm[key]; found {
+		entry := elem.Value.(*lruEntry)
+		if !entry.val.Expired(time.Now().UnixNano()) {
+			// 当key重复设置的时候
+			log.Printf("this key %s is already existed!\n", key)
+			return nil
+		}
+		// The old entry is already expired but hasn't been swept by the
+		// janitor yet; drop it now instead of bouncing this Set off the
+		// still-present-but-dead map entry.
+		k, v := c.removeElement(elem, true)
+		evicted = append(evicted, evictedItem{k, v.Value})
 	}
 
-	c.Set(key, val, d)
+	switch c.policy {
+	case EvictLRU, EvictLFU:
+		if size > c.Memory {
+			// No amount of eviction makes this one value fit; reject it
+			// up front instead of wiping every existing entry for nothing.
+			log.Println("cache is full,this val can't be saved", ", val: ", val)
+			return evicted
+		}
+		for c.CurMemory+size > c.Memory && c.ll.Len() > 0 {
+			k, v := c.evictOne()
+			evicted = append(evicted, evictedItem{k, v.Value})
+		}
+		if c.CurMemory+size > c.Memory {
+			log.Println("cache is full,this val can't be saved", ", val: ", val)
+			return evicted
+		}
+	default:
+		if (c.Memory - c.CurMemory) < size {
+			log.Println("cache is full,this val can't be saved", ", val: ", val)
+			return evicted
+		}
+	}
+
+	c.Iterm[key] = c.ll.PushFront(&lruEntry{key: key, val: build(size)})
+	c.CurMemory += size
+	return evicted
+}
+
+func (c *SowhyCache) Get(key string) (interface{}, bool) {
+	c.Lock.Lock()
+	defer c.Lock.Unlock()
+	return c.getLocked(key, time.Now().UnixNano())
 }
 
-func (c *SowhyCache) set(key string, val interface{}, d time.Duration) {
-	c.Iterm[key] = &Value{
-		Value: val,
+// getLocked performs the body of Get. Callers must hold c.Lock; GetMulti
+// uses this to look up several keys in the same shard under one lock.
+func (c *SowhyCache) getLocked(key string, now int64) (interface{}, bool) {
+	elem, found := c.Iterm[key]
+	if !found {
+		atomic.AddInt64(&c.misses, 1)
+		log.Printf("there is no key named %s!", key)
+		return nil, false
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if entry.val.Expired(now) {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
 	}
 
-	// 判断是否有过期时间
-	if d > 0 {
-		c.Iterm[key].ExpireTime = time.NewTimer(d)
-		go c.run(key)
+	atomic.AddInt64(&c.hits, 1)
+	entry.val.freq++
+	if entry.val.Sliding {
+		entry.val.LastAccess = now
+	}
+	if c.policy == EvictLRU {
+		c.ll.MoveToFront(elem)
 	}
+	return entry.val.Value, true
 }
 
-func (c *SowhyCache) run(key string) {
-	<-c.Iterm[key].ExpireTime.C
-	c.Del(key)
+// Touch renews key's expiration without returning its value: for a
+// sliding entry it resets the idle clock to now (extend is ignored), for a
+// fixed-TTL entry it sets Expiration to extend from now, and an entry that
+// never expires is left as is. It reports whether key was found and not
+// already expired.
+func (c *SowhyCache) Touch(key string, extend time.Duration) bool {
+	now := time.Now().UnixNano()
+
+	c.Lock.Lock()
+	defer c.Lock.Unlock()
+
+	elem, found := c.Iterm[key]
+	if !found {
+		return false
+	}
+	entry := elem.Value.(*lruEntry)
+	if entry.val.Expired(now) {
+		return false
+	}
+
+	if entry.val.Sliding {
+		entry.val.LastAccess = now
+	} else if entry.val.Expiration > 0 {
+		entry.val.Expiration = now + int64(extend)
+	}
+	return true
 }
 
-func (c *SowhyCache) Get(key string) (interface{}, bool) {
+// TTL reports the remaining time until key expires. If key never expires
+// it returns (0, true). If key is missing or already expired it returns
+// (0, false).
+func (c *SowhyCache) TTL(key string) (time.Duration, bool) {
+	now := time.Now().UnixNano()
+
 	c.Lock.RLock()
 	defer c.Lock.RUnlock()
-	if val, found := c.Iterm[key]; found {
-		return val, true
+
+	elem, found := c.Iterm[key]
+	if !found {
+		return 0, false
+	}
+	entry := elem.Value.(*lruEntry)
+	if entry.val.Expired(now) {
+		return 0, false
+	}
+
+	switch {
+	case entry.val.Sliding:
+		if entry.val.Idle <= 0 {
+			return 0, true
+		}
+		return time.Duration(entry.val.LastAccess + int64(entry.val.Idle) - now), true
+	case entry.val.Expiration == 0:
+		return 0, true
+	default:
+		return time.Duration(entry.val.Expiration - now), true
 	}
-	err := fmt.Sprintf("there is no key named %s!", key)
-	return errors.New(err), false
 }
 
 func (c *SowhyCache) Del(key string) bool {
 	c.Lock.Lock()
-	if _, found := c.Iterm[key]; found {
-		delete(c.Iterm, key)
+	elem, found := c.Iterm[key]
+	if !found {
+		log.Printf("there is no key named %s!", key)
 		c.Lock.Unlock()
-		return true
+		return false
 	}
-	log.Printf("there is no key named %s!", key)
+	_, val := c.removeElement(elem, false)
+	onEvicted := c.onEvicted
 	c.Lock.Unlock()
-	return false
+
+	if onEvicted != nil {
+		onEvicted(key, val.Value)
+	}
+	return true
 }
 
+// Exists reports whether key is present and not expired. It shares
+// Get/Set's notion of "present" by checking Value.Expired rather than raw
+// map membership, lazily dropping the entry if it finds one that expired
+// but hasn't been swept by the janitor yet.
 func (c *SowhyCache) Exists(key string) bool {
-	c.Lock.RLock()
-	defer c.Lock.RUnlock()
-	if _, found := c.Iterm[key]; found {
-		c.Lock.RUnlock()
+	c.Lock.Lock()
+
+	elem, found := c.Iterm[key]
+	if !found {
+		c.Lock.Unlock()
+		return false
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if !entry.val.Expired(time.Now().UnixNano()) {
+		c.Lock.Unlock()
 		return true
 	}
+
+	_, val := c.removeElement(elem, true)
+	onEvicted := c.onEvicted
+	c.Lock.Unlock()
+
+	if onEvicted != nil {
+		onEvicted(key, val.Value)
+	}
 	return false
 }
 
+// Flush removes every entry from the cache. It replaces the previous
+// implementation, which drove deletion through the per-key expiration
+// timer and deadlocked by calling run (which blocks on that timer) while
+// still holding c.Lock.
 func (c *SowhyCache) Flush() bool {
 	c.Lock.Lock()
-	for key, _ := range c.Iterm {
-		if c.Iterm[key].ExpireTime == nil {
-			c.Iterm[key].ExpireTime = time.NewTimer(0)
-			c.run(key)
-		} else {
-			c.Iterm[key].ExpireTime.Reset(0)
-		}
-	}
+	c.Iterm = make(map[string]*list.Element)
+	c.ll.Init()
+	c.CurMemory = 0
 	c.Lock.Unlock()
 	return true
 }
@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSetGetDel(t *testing.T) {
+	c := NewSowhyCache()
+	defer c.Close()
+
+	c.Set("a", 1, 0)
+	v, found := c.Get("a")
+	if !found || v.(int) != 1 {
+		t.Fatalf("Get(a) = %v, %v; want 1, true", v, found)
+	}
+
+	if !c.Del("a") {
+		t.Fatal("Del(a) = false; want true")
+	}
+	if _, found := c.Get("a"); found {
+		t.Fatal("Get(a) found entry after Del")
+	}
+}
+
+func TestExpirationViaGet(t *testing.T) {
+	c := NewSowhyCache(WithJanitorInterval(time.Hour))
+	defer c.Close()
+
+	c.Set("a", 1, 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+
+	if _, found := c.Get("a"); found {
+		t.Fatal("Get(a) found an entry that should have expired")
+	}
+}
+
+func TestSetAfterExpiryOverwritesStaleEntry(t *testing.T) {
+	// Janitor interval is long enough that the sweep can't race with the
+	// second Set below; the stale entry must be evicted lazily instead.
+	c := NewSowhyCache(WithJanitorInterval(time.Hour))
+	defer c.Close()
+
+	c.Set("k", 1, 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+
+	c.Set("k", 2, time.Minute)
+	v, found := c.Get("k")
+	if !found {
+		t.Fatal("Get(k) = not found; want the freshly Set value")
+	}
+	if v.(int) != 2 {
+		t.Fatalf("Get(k) = %v; want 2", v)
+	}
+}
+
+func TestExistsReflectsExpiration(t *testing.T) {
+	c := NewSowhyCache(WithJanitorInterval(time.Hour))
+	defer c.Close()
+
+	c.Set("k", 1, 10*time.Millisecond)
+	if !c.Exists("k") {
+		t.Fatal("Exists(k) = false before expiry; want true")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if c.Exists("k") {
+		t.Fatal("Exists(k) = true after expiry; want false, matching Get")
+	}
+	if _, found := c.Get("k"); found {
+		t.Fatal("Get(k) = found after expiry; want false")
+	}
+}
+
+func TestJanitorSweepsExpiredEntries(t *testing.T) {
+	c := NewSowhyCache(WithJanitorInterval(5 * time.Millisecond))
+	defer c.Close()
+
+	var evictedKey string
+	var mu sync.Mutex
+	c.SetOnEvicted(func(key string, val interface{}) {
+		mu.Lock()
+		evictedKey = key
+		mu.Unlock()
+	})
+
+	c.Set("k", 1, 10*time.Millisecond)
+
+	deadline := time.Now().Add(200 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		got := evictedKey
+		mu.Unlock()
+		if got == "k" {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("janitor never fired OnEvicted for the expired key")
+}
+
+func TestCloseIsIdempotent(t *testing.T) {
+	c := NewSowhyCache(WithJanitorInterval(time.Millisecond))
+	c.Close()
+	c.Close()
+}
+
+func TestFlushRemovesEverything(t *testing.T) {
+	c := NewSowhyCache()
+	defer c.Close()
+
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+	c.Flush()
+
+	if c.Keys() != 0 {
+		t.Fatalf("Keys() = %d after Flush; want 0", c.Keys())
+	}
+	if c.CurMemory != 0 {
+		t.Fatalf("CurMemory = %d after Flush; want 0", c.CurMemory)
+	}
+}
+
+func TestConcurrentSetGet(t *testing.T) {
+	c := NewSowhyCache()
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := string(rune('a' + i%26))
+			c.Set(key, i, 0)
+			c.Get(key)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewSowhyCache(WithJanitorInterval(time.Hour))
+	defer c.Close()
+	c.SetEvictionPolicy(EvictLRU)
+	c.Memory = 3 * int64(sizeof(reflect.ValueOf(0)))
+
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+	c.Set("c", 3, 0)
+
+	// Touch "a" so it's no longer the least recently used entry.
+	if _, found := c.Get("a"); !found {
+		t.Fatal("Get(a) = not found before eviction")
+	}
+
+	// This push should evict "b" (now the least recently used), not "a".
+	c.Set("d", 4, 0)
+
+	if _, found := c.Get("b"); found {
+		t.Fatal("Get(b) = found; want evicted as least recently used")
+	}
+	if _, found := c.Get("a"); !found {
+		t.Fatal("Get(a) = not found; want survived because it was touched")
+	}
+	if _, found := c.Get("c"); !found {
+		t.Fatal("Get(c) = not found; want survived")
+	}
+	if _, found := c.Get("d"); !found {
+		t.Fatal("Get(d) = not found; want the entry that triggered eviction")
+	}
+
+	if got := c.Stats().Evictions; got < 1 {
+		t.Fatalf("Stats().Evictions = %d; want at least 1", got)
+	}
+}
+
+func TestLRUOversizedValueIsRejectedNotEvictedInto(t *testing.T) {
+	c := NewSowhyCache(WithJanitorInterval(time.Hour))
+	defer c.Close()
+	c.SetEvictionPolicy(EvictLRU)
+
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+	c.Memory = c.CurMemory + 1 // just enough for the two small entries, no more
+
+	// A value far too big to ever fit must be rejected outright, not evict
+	// every existing entry and still overrun the budget.
+	huge := make([]byte, 10000)
+	c.Set("huge", huge, 0)
+
+	if _, found := c.Get("huge"); found {
+		t.Fatal("Get(huge) = found; the oversized value should have been rejected")
+	}
+	if _, found := c.Get("a"); !found {
+		t.Fatal("Get(a) = not found; surviving entries should not be evicted for a value that can never fit")
+	}
+	if _, found := c.Get("b"); !found {
+		t.Fatal("Get(b) = not found; surviving entries should not be evicted for a value that can never fit")
+	}
+	if c.CurMemory > c.Memory {
+		t.Fatalf("CurMemory = %d exceeds Memory = %d after a rejected oversized Set", c.CurMemory, c.Memory)
+	}
+}
+
+func TestLFUEvictsLeastFrequentlyUsed(t *testing.T) {
+	c := NewSowhyCache(WithJanitorInterval(time.Hour))
+	defer c.Close()
+	c.SetEvictionPolicy(EvictLFU)
+	c.Memory = 3 * int64(sizeof(reflect.ValueOf(0)))
+
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+	c.Set("c", 3, 0)
+
+	// Access "a" and "c" repeatedly so "b" is the least frequently used.
+	for i := 0; i < 3; i++ {
+		c.Get("a")
+		c.Get("c")
+	}
+
+	c.Set("d", 4, 0)
+
+	if _, found := c.Get("b"); found {
+		t.Fatal("Get(b) = found; want evicted as least frequently used")
+	}
+	if _, found := c.Get("a"); !found {
+		t.Fatal("Get(a) = not found; want survived due to higher access frequency")
+	}
+	if _, found := c.Get("c"); !found {
+		t.Fatal("Get(c) = not found; want survived due to higher access frequency")
+	}
+}
+
+func TestStatsTracksHitsMissesAndBytes(t *testing.T) {
+	c := NewSowhyCache(WithJanitorInterval(time.Hour))
+	defer c.Close()
+
+	c.Set("a", 1, 0)
+	c.Get("a")
+	c.Get("missing")
+
+	stats := c.Stats()
+	if stats.Hits != 1 {
+		t.Fatalf("Stats().Hits = %d; want 1", stats.Hits)
+	}
+	if stats.Misses != 1 {
+		t.Fatalf("Stats().Misses = %d; want 1", stats.Misses)
+	}
+	if stats.Bytes != c.CurMemory {
+		t.Fatalf("Stats().Bytes = %d; want %d (CurMemory)", stats.Bytes, c.CurMemory)
+	}
+}
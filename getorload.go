@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// GetOrLoad returns the cached value for key, calling loader to populate it
+// on a miss. Concurrent misses for the same key share a single loader call
+// via singleflight, so a hot key under load only ever triggers one loader
+// invocation; all callers waiting on it receive its result (or its error).
+// On error nothing is cached, so the next call retries the loader.
+func (c *SowhyCache) GetOrLoad(key string, expire time.Duration, loader func() (interface{}, error)) (interface{}, error) {
+	if val, found := c.Get(key); found {
+		return val, nil
+	}
+
+	v, err, _ := c.sf.Do(key, func() (interface{}, error) {
+		val, err := loader()
+		if err != nil {
+			return nil, err
+		}
+		c.Set(key, val, expire)
+		return val, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// GetOrLoadCtx behaves like GetOrLoad, except a waiter returns ctx.Err()
+// as soon as ctx is done instead of blocking on the loader. The loader
+// call itself is not canceled: it keeps running in the background so
+// other waiters (and the cache) still see its result.
+func (c *SowhyCache) GetOrLoadCtx(ctx context.Context, key string, expire time.Duration, loader func() (interface{}, error)) (interface{}, error) {
+	if val, found := c.Get(key); found {
+		return val, nil
+	}
+
+	resultCh := c.sf.DoChan(key, func() (interface{}, error) {
+		val, err := loader()
+		if err != nil {
+			return nil, err
+		}
+		c.Set(key, val, expire)
+		return val, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-resultCh:
+		return res.Val, res.Err
+	}
+}
@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetOrLoadDedupsConcurrentMisses(t *testing.T) {
+	c := NewSowhyCache(WithJanitorInterval(time.Hour))
+	defer c.Close()
+
+	var calls int64
+	loader := func() (interface{}, error) {
+		atomic.AddInt64(&calls, 1)
+		time.Sleep(20 * time.Millisecond)
+		return "value", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := c.GetOrLoad("k", time.Minute, loader)
+			if err != nil {
+				t.Errorf("GetOrLoad: %v", err)
+			}
+			if v != "value" {
+				t.Errorf("GetOrLoad = %v; want \"value\"", v)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt64(&calls); got != 1 {
+		t.Fatalf("loader called %d times; want exactly 1", got)
+	}
+
+	if v, found := c.Get("k"); !found || v != "value" {
+		t.Fatalf("Get(k) = %v, %v; want \"value\", true", v, found)
+	}
+}
+
+func TestGetOrLoadErrorNotCached(t *testing.T) {
+	c := NewSowhyCache(WithJanitorInterval(time.Hour))
+	defer c.Close()
+
+	wantErr := errors.New("load failed")
+	loader := func() (interface{}, error) {
+		return nil, wantErr
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.GetOrLoad("k", time.Minute, loader); !errors.Is(err, wantErr) {
+				t.Errorf("GetOrLoad error = %v; want %v", err, wantErr)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, found := c.Get("k"); found {
+		t.Fatal("Get(k) = found; a failed loader must not populate the cache")
+	}
+}
+
+func TestGetOrLoadCtxCancellation(t *testing.T) {
+	c := NewSowhyCache(WithJanitorInterval(time.Hour))
+	defer c.Close()
+
+	loaderDone := make(chan struct{})
+	loader := func() (interface{}, error) {
+		time.Sleep(50 * time.Millisecond)
+		close(loaderDone)
+		return "value", nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.GetOrLoadCtx(ctx, "k", time.Minute, loader)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("GetOrLoadCtx error = %v; want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed >= 50*time.Millisecond {
+		t.Fatalf("GetOrLoadCtx returned after %v; want it to return promptly on ctx cancellation, not wait for the loader", elapsed)
+	}
+
+	// The loader keeps running in the background and still populates the
+	// cache for later callers even though this waiter already gave up.
+	<-loaderDone
+	// The cache write happens after the loader returns, so join the
+	// still-in-flight call (or hit the cache) to wait for that Set.
+	c.GetOrLoad("k", time.Minute, func() (interface{}, error) {
+		return nil, errors.New("loader called again")
+	})
+	if v, found := c.Get("k"); !found || v != "value" {
+		t.Fatalf("Get(k) = %v, %v; want the background loader to have populated the cache", v, found)
+	}
+}
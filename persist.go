@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"encoding/gob"
+	"io"
+	"os"
+	"reflect"
+	"time"
+)
+
+// persistedItem is the on-the-wire representation of one cache entry used
+// by Save/Load. Expiration is kept as an absolute nanosecond timestamp, 0
+// meaning "no expiration". Sliding/Idle/LastAccess mirror the matching
+// fields on Value so a SetSliding entry round-trips instead of coming
+// back as a permanent one.
+type persistedItem struct {
+	Key        string
+	Value      interface{}
+	Expiration int64
+	Sliding    bool
+	Idle       time.Duration
+	LastAccess int64
+}
+
+// Save writes a gob-encoded snapshot of the cache to w. Callers that store
+// concrete types other than the predeclared Go kinds must gob.Register
+// them before calling Save or Load, or encoding/gob will fail to
+// (de)serialize the Value field.
+func (c *SowhyCache) Save(w io.Writer) error {
+	c.Lock.RLock()
+	defer c.Lock.RUnlock()
+
+	items := make([]persistedItem, 0, len(c.Iterm))
+	for _, elem := range c.Iterm {
+		entry := elem.Value.(*lruEntry)
+		items = append(items, persistedItem{
+			Key:        entry.key,
+			Value:      entry.val.Value,
+			Expiration: entry.val.Expiration,
+			Sliding:    entry.val.Sliding,
+			Idle:       entry.val.Idle,
+			LastAccess: entry.val.LastAccess,
+		})
+	}
+
+	return gob.NewEncoder(w).Encode(items)
+}
+
+// SaveFile is a convenience wrapper around Save that creates (or
+// truncates) path and writes the snapshot to it.
+func (c *SowhyCache) SaveFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.Save(f)
+}
+
+// Load decodes a snapshot written by Save and re-inserts its entries,
+// skipping any that have already expired. CurMemory is rebuilt from
+// sizeof rather than trusted from the snapshot, and entries whose key
+// already exists in the cache are left untouched.
+func (c *SowhyCache) Load(r io.Reader) error {
+	var items []persistedItem
+	if err := gob.NewDecoder(r).Decode(&items); err != nil {
+		return err
+	}
+
+	now := time.Now().UnixNano()
+
+	c.Lock.Lock()
+	defer c.Lock.Unlock()
+
+	for _, it := range items {
+		v := &Value{
+			Value:      it.Value,
+			Expiration: it.Expiration,
+			Sliding:    it.Sliding,
+			Idle:       it.Idle,
+			LastAccess: it.LastAccess,
+		}
+		if v.Expired(now) {
+			continue
+		}
+		if _, found := c.Iterm[it.Key]; found {
+			continue
+		}
+
+		size := int64(sizeof(reflect.ValueOf(it.Value)))
+		v.Size = size
+		c.Iterm[it.Key] = c.ll.PushFront(&lruEntry{key: it.Key, val: v})
+		c.CurMemory += size
+	}
+
+	return nil
+}
+
+// LoadFile is a convenience wrapper around Load that reads the snapshot
+// from path.
+func (c *SowhyCache) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.Load(f)
+}
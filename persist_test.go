@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveLoadRoundTripsPlainEntry(t *testing.T) {
+	c := NewSowhyCache(WithJanitorInterval(time.Hour))
+	defer c.Close()
+
+	c.Set("a", 1, time.Minute)
+	c.Set("b", "payload", 0)
+
+	var buf bytes.Buffer
+	if err := c.Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded := NewSowhyCache(WithJanitorInterval(time.Hour))
+	defer loaded.Close()
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	v, found := loaded.Get("a")
+	if !found || v.(int) != 1 {
+		t.Fatalf("Get(a) = %v, %v; want 1, true", v, found)
+	}
+	v, found = loaded.Get("b")
+	if !found || v.(string) != "payload" {
+		t.Fatalf("Get(b) = %v, %v; want \"payload\", true", v, found)
+	}
+
+	ttl, found := loaded.TTL("a")
+	if !found || ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("TTL(a) = %v, %v; want in (0, 1m]", ttl, found)
+	}
+}
+
+func TestSaveLoadFileRoundTrip(t *testing.T) {
+	c := NewSowhyCache(WithJanitorInterval(time.Hour))
+	defer c.Close()
+
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+
+	path := filepath.Join(t.TempDir(), "snapshot.gob")
+	if err := c.SaveFile(path); err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+
+	loaded := NewSowhyCache(WithJanitorInterval(time.Hour))
+	defer loaded.Close()
+	if err := loaded.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+
+	if v, found := loaded.Get("a"); !found || v.(int) != 1 {
+		t.Fatalf("Get(a) = %v, %v; want 1, true", v, found)
+	}
+	if v, found := loaded.Get("b"); !found || v.(int) != 2 {
+		t.Fatalf("Get(b) = %v, %v; want 2, true", v, found)
+	}
+}
+
+func TestLoadSkipsExpiredEntries(t *testing.T) {
+	c := NewSowhyCache(WithJanitorInterval(time.Hour))
+	defer c.Close()
+
+	c.Set("stale", 1, 10*time.Millisecond)
+	c.Set("fresh", 2, time.Minute)
+	time.Sleep(20 * time.Millisecond)
+
+	var buf bytes.Buffer
+	if err := c.Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded := NewSowhyCache(WithJanitorInterval(time.Hour))
+	defer loaded.Close()
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if _, found := loaded.Get("stale"); found {
+		t.Fatal("Get(stale) = found; an already-expired entry must be skipped on Load")
+	}
+	if _, found := loaded.Get("fresh"); !found {
+		t.Fatal("Get(fresh) = not found; a still-valid entry must survive Load")
+	}
+}
+
+func TestShardedCacheSaveLoadRoundTrip(t *testing.T) {
+	sc := NewShardedCache(16)
+	defer func() {
+		for _, shard := range sc.shards {
+			shard.Close()
+		}
+	}()
+
+	for i := 0; i < 50; i++ {
+		sc.Set(string(rune('a'+i%26))+string(rune('0'+i%10)), i, 0)
+	}
+
+	var buf bytes.Buffer
+	if err := sc.Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded := NewShardedCache(16)
+	defer func() {
+		for _, shard := range loaded.shards {
+			shard.Close()
+		}
+	}()
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if got := loaded.Keys(); got != 50 {
+		t.Fatalf("Keys() = %d; want 50", got)
+	}
+	for i := 0; i < 50; i++ {
+		key := string(rune('a'+i%26)) + string(rune('0'+i%10))
+		if v, found := loaded.Get(key); !found || v.(int) != i {
+			t.Fatalf("Get(%s) = %v, %v; want %d, true", key, v, found, i)
+		}
+	}
+}
+
+func TestShardedCacheSaveLoadFileRoundTrip(t *testing.T) {
+	sc := NewShardedCache(8)
+	defer func() {
+		for _, shard := range sc.shards {
+			shard.Close()
+		}
+	}()
+
+	sc.Set("a", 1, 0)
+	sc.Set("b", 2, 0)
+
+	path := filepath.Join(t.TempDir(), "sharded.gob")
+	if err := sc.SaveFile(path); err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+
+	loaded := NewShardedCache(8)
+	defer func() {
+		for _, shard := range loaded.shards {
+			shard.Close()
+		}
+	}()
+	if err := loaded.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+
+	if v, found := loaded.Get("a"); !found || v.(int) != 1 {
+		t.Fatalf("Get(a) = %v, %v; want 1, true", v, found)
+	}
+	if v, found := loaded.Get("b"); !found || v.(int) != 2 {
+		t.Fatalf("Get(b) = %v, %v; want 2, true", v, found)
+	}
+}
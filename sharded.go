@@ -0,0 +1,255 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/gob"
+	"hash/fnv"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+// DefaultShardCount is the shard count NewShardedCache uses when n <= 0.
+const DefaultShardCount = 256
+
+// cacheEntry is the per-key payload accepted by ShardedCache.SetMulti.
+type cacheEntry struct {
+	Value interface{}
+}
+
+// ShardedCache spreads keys across N independent SowhyCache shards, each
+// with its own lock, memory budget, and janitor, so concurrent writers
+// contend on one shard's sync.RWMutex instead of a single cache-wide lock.
+// It satisfies the Cache interface so it can be swapped in for a plain
+// SowhyCache transparently.
+type ShardedCache struct {
+	shards []*SowhyCache
+	mask   uint32
+}
+
+// NewShardedCache creates a ShardedCache with n shards, rounded up to the
+// next power of two (default DefaultShardCount), each built via
+// NewSowhyCache(opts...) and given an equal share of DefaultMemory.
+func NewShardedCache(n int, opts ...Option) *ShardedCache {
+	if n <= 0 {
+		n = DefaultShardCount
+	}
+	n = nextPowerOfTwo(n)
+
+	sc := &ShardedCache{
+		shards: make([]*SowhyCache, n),
+		mask:   uint32(n - 1),
+	}
+	for i := range sc.shards {
+		shard := NewSowhyCache(opts...)
+		shard.Memory = DefaultMemory / int64(n)
+		sc.shards[i] = shard
+	}
+	return sc
+}
+
+func nextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
+
+func fnv32(key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return h.Sum32()
+}
+
+func (sc *ShardedCache) shardFor(key string) *SowhyCache {
+	return sc.shards[fnv32(key)&sc.mask]
+}
+
+func (sc *ShardedCache) SetMaxMemory(size string) bool {
+	bytes, err := parseMemorySize(size)
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+
+	perShard := bytes / int64(len(sc.shards))
+	for _, shard := range sc.shards {
+		shard.Lock.Lock()
+		if shard.CurMemory >= perShard {
+			log.Println("can't reset MaxMemory!")
+		}
+		shard.Memory = perShard
+		shard.Lock.Unlock()
+	}
+	return true
+}
+
+func (sc *ShardedCache) Set(key string, val interface{}, d time.Duration) {
+	sc.shardFor(key).Set(key, val, d)
+}
+
+func (sc *ShardedCache) Get(key string) (interface{}, bool) {
+	return sc.shardFor(key).Get(key)
+}
+
+func (sc *ShardedCache) Del(key string) bool {
+	return sc.shardFor(key).Del(key)
+}
+
+func (sc *ShardedCache) Exists(key string) bool {
+	return sc.shardFor(key).Exists(key)
+}
+
+// Flush clears every shard.
+func (sc *ShardedCache) Flush() bool {
+	ok := true
+	for _, shard := range sc.shards {
+		ok = shard.Flush() && ok
+	}
+	return ok
+}
+
+// Keys returns the total number of entries across all shards.
+func (sc *ShardedCache) Keys() int64 {
+	var total int64
+	for _, shard := range sc.shards {
+		total += shard.Keys()
+	}
+	return total
+}
+
+// Stats aggregates hit/miss/eviction counters and byte counts across all
+// shards.
+func (sc *ShardedCache) Stats() Stats {
+	var agg Stats
+	for _, shard := range sc.shards {
+		s := shard.Stats()
+		agg.Hits += s.Hits
+		agg.Misses += s.Misses
+		agg.Evictions += s.Evictions
+		agg.Bytes += s.Bytes
+	}
+	return agg
+}
+
+// GetMulti looks up several keys at once, grouping them by shard so each
+// shard is locked only once for the whole batch rather than once per key.
+// Missing or expired keys are simply absent from the result.
+func (sc *ShardedCache) GetMulti(keys []string) map[string]interface{} {
+	grouped := make(map[*SowhyCache][]string)
+	for _, key := range keys {
+		shard := sc.shardFor(key)
+		grouped[shard] = append(grouped[shard], key)
+	}
+
+	now := time.Now().UnixNano()
+	result := make(map[string]interface{}, len(keys))
+	for shard, shardKeys := range grouped {
+		shard.Lock.Lock()
+		for _, key := range shardKeys {
+			if val, found := shard.getLocked(key, now); found {
+				result[key] = val
+			}
+		}
+		shard.Lock.Unlock()
+	}
+	return result
+}
+
+// SetMulti writes several entries at once, all expiring after the same
+// duration, grouping keys by shard so each shard is locked only once for
+// the whole batch rather than once per key.
+func (sc *ShardedCache) SetMulti(entries map[string]cacheEntry, expire time.Duration) {
+	grouped := make(map[*SowhyCache]map[string]cacheEntry)
+	for key, entry := range entries {
+		shard := sc.shardFor(key)
+		if grouped[shard] == nil {
+			grouped[shard] = make(map[string]cacheEntry)
+		}
+		grouped[shard][key] = entry
+	}
+
+	for shard, shardEntries := range grouped {
+		shard.Lock.Lock()
+		var evicted []evictedItem
+		for key, entry := range shardEntries {
+			val := entry.Value
+			evicted = append(evicted, shard.setLocked(key, val, func(size int64) *Value {
+				v := &Value{Value: val, Size: size}
+				if expire > 0 {
+					v.Expiration = time.Now().Add(expire).UnixNano()
+				}
+				return v
+			})...)
+		}
+		onEvicted := shard.onEvicted
+		shard.Lock.Unlock()
+
+		if onEvicted != nil {
+			for _, e := range evicted {
+				onEvicted(e.key, e.val)
+			}
+		}
+	}
+}
+
+// Save writes a gob-encoded snapshot of every shard to w: the shard count,
+// followed by each shard's own Save stream in order. See SowhyCache.Save
+// for the gob.Register caveat.
+func (sc *ShardedCache) Save(w io.Writer) error {
+	if err := gob.NewEncoder(w).Encode(len(sc.shards)); err != nil {
+		return err
+	}
+	for _, shard := range sc.shards {
+		if err := shard.Save(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveFile is a convenience wrapper around Save that creates (or
+// truncates) path and writes the snapshot to it.
+func (sc *ShardedCache) SaveFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return sc.Save(f)
+}
+
+// Load decodes a snapshot written by Save. The shard count must match;
+// extra shards recorded in the snapshot beyond the current shard count are
+// skipped. r is wrapped in a single bufio.Reader shared across every
+// shard's Load call: each shard.Load creates its own gob.Decoder, and
+// gob wraps any reader that isn't already an io.ByteReader in a fresh
+// bufio.Reader of its own, whose read-ahead would otherwise swallow
+// bytes belonging to the next shard's stream.
+func (sc *ShardedCache) Load(r io.Reader) error {
+	br := bufio.NewReader(r)
+	dec := gob.NewDecoder(br)
+	var n int
+	if err := dec.Decode(&n); err != nil {
+		return err
+	}
+	for i := 0; i < n && i < len(sc.shards); i++ {
+		if err := sc.shards[i].Load(br); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadFile is a convenience wrapper around Load that reads the snapshot
+// from path.
+func (sc *ShardedCache) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return sc.Load(f)
+}
@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestShardedCacheSetGetDel(t *testing.T) {
+	sc := NewShardedCache(16)
+	defer func() {
+		for _, shard := range sc.shards {
+			shard.Close()
+		}
+	}()
+
+	sc.Set("a", 1, 0)
+	v, found := sc.Get("a")
+	if !found || v.(int) != 1 {
+		t.Fatalf("Get(a) = %v, %v; want 1, true", v, found)
+	}
+
+	if !sc.Del("a") {
+		t.Fatal("Del(a) = false; want true")
+	}
+	if sc.Exists("a") {
+		t.Fatal("Exists(a) = true after Del")
+	}
+}
+
+func TestShardedCacheKeysAndFlush(t *testing.T) {
+	sc := NewShardedCache(16)
+	defer func() {
+		for _, shard := range sc.shards {
+			shard.Close()
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		sc.Set(fmt.Sprintf("key-%d", i), i, 0)
+	}
+	if got := sc.Keys(); got != 100 {
+		t.Fatalf("Keys() = %d; want 100", got)
+	}
+
+	sc.Flush()
+	if got := sc.Keys(); got != 0 {
+		t.Fatalf("Keys() = %d after Flush; want 0", got)
+	}
+}
+
+func TestShardedCacheGetSetMulti(t *testing.T) {
+	sc := NewShardedCache(16)
+	defer func() {
+		for _, shard := range sc.shards {
+			shard.Close()
+		}
+	}()
+
+	entries := map[string]cacheEntry{
+		"a": {Value: 1},
+		"b": {Value: 2},
+		"c": {Value: 3},
+	}
+	sc.SetMulti(entries, time.Minute)
+
+	got := sc.GetMulti([]string{"a", "b", "c", "missing"})
+	if len(got) != 3 {
+		t.Fatalf("GetMulti returned %d entries; want 3", len(got))
+	}
+	for k, want := range map[string]int{"a": 1, "b": 2, "c": 3} {
+		if got[k].(int) != want {
+			t.Errorf("GetMulti[%s] = %v; want %d", k, got[k], want)
+		}
+	}
+	if _, found := got["missing"]; found {
+		t.Fatal("GetMulti returned a value for a key that was never set")
+	}
+}
+
+func TestShardedCacheDistributesKeysAcrossShards(t *testing.T) {
+	sc := NewShardedCache(16)
+	defer func() {
+		for _, shard := range sc.shards {
+			shard.Close()
+		}
+	}()
+
+	used := make(map[int]bool)
+	for i := 0; i < 200; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		for idx, shard := range sc.shards {
+			if shard == sc.shardFor(key) {
+				used[idx] = true
+				break
+			}
+		}
+	}
+	if len(used) < 2 {
+		t.Fatalf("200 distinct keys landed on only %d shard(s); expected spread across shards", len(used))
+	}
+}
+
+func TestShardedCacheStatsAggregatesShards(t *testing.T) {
+	sc := NewShardedCache(16)
+	defer func() {
+		for _, shard := range sc.shards {
+			shard.Close()
+		}
+	}()
+
+	sc.Set("a", 1, 0)
+	sc.Get("a")
+	sc.Get("missing")
+
+	stats := sc.Stats()
+	if stats.Hits < 1 {
+		t.Fatalf("Stats().Hits = %d; want at least 1", stats.Hits)
+	}
+	if stats.Misses < 1 {
+		t.Fatalf("Stats().Misses = %d; want at least 1", stats.Misses)
+	}
+}
+
+func TestShardedCacheConcurrentAccess(t *testing.T) {
+	sc := NewShardedCache(32)
+	defer func() {
+		for _, shard := range sc.shards {
+			shard.Close()
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("key-%d", i)
+			sc.Set(key, i, 0)
+			sc.Get(key)
+		}(i)
+	}
+	wg.Wait()
+}
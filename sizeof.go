@@ -0,0 +1,53 @@
+package cache
+
+import "reflect"
+
+// sizeof estimates the memory footprint of v in bytes. It's a best-effort
+// accounting used to track CurMemory against Memory budgets; it doesn't
+// need to be exact, just cheap and roughly proportional to actual size.
+func sizeof(v reflect.Value) int {
+	if !v.IsValid() {
+		return 0
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return int(v.Type().Size())
+		}
+		return int(v.Type().Size()) + sizeof(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			return int(v.Type().Size())
+		}
+		return int(v.Type().Size()) + sizeof(v.Elem())
+	case reflect.String:
+		return int(v.Type().Size()) + v.Len()
+	case reflect.Slice:
+		size := int(v.Type().Size())
+		for i := 0; i < v.Len(); i++ {
+			size += sizeof(v.Index(i))
+		}
+		return size
+	case reflect.Array:
+		size := 0
+		for i := 0; i < v.Len(); i++ {
+			size += sizeof(v.Index(i))
+		}
+		return size
+	case reflect.Map:
+		size := int(v.Type().Size())
+		for _, key := range v.MapKeys() {
+			size += sizeof(key) + sizeof(v.MapIndex(key))
+		}
+		return size
+	case reflect.Struct:
+		size := 0
+		for i := 0; i < v.NumField(); i++ {
+			size += sizeof(v.Field(i))
+		}
+		return size
+	default:
+		return int(v.Type().Size())
+	}
+}
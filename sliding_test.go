@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestSlidingExpirationRenewedByGet(t *testing.T) {
+	c := NewSowhyCache(WithJanitorInterval(time.Hour))
+	defer c.Close()
+
+	c.SetSliding("session", "payload", 30*time.Millisecond)
+
+	// Keep touching the entry via Get for longer than the idle window;
+	// it should stay alive because each Get resets the idle clock.
+	deadline := time.Now().Add(80 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		if _, found := c.Get("session"); !found {
+			t.Fatal("sliding entry expired despite repeated Get access")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Stop touching it; it must expire after the idle window elapses.
+	time.Sleep(50 * time.Millisecond)
+	if _, found := c.Get("session"); found {
+		t.Fatal("sliding entry still present after the idle window elapsed untouched")
+	}
+}
+
+func TestTouchRenewsSlidingEntry(t *testing.T) {
+	c := NewSowhyCache(WithJanitorInterval(time.Hour))
+	defer c.Close()
+
+	c.SetSliding("session", "payload", 30*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+
+	if !c.Touch("session", 0) {
+		t.Fatal("Touch(session) = false; want true")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, found := c.Get("session"); !found {
+		t.Fatal("Get(session) = not found; Touch should have renewed the idle window")
+	}
+}
+
+func TestTTLReportsRemainingTime(t *testing.T) {
+	c := NewSowhyCache(WithJanitorInterval(time.Hour))
+	defer c.Close()
+
+	c.Set("k", 1, time.Minute)
+	ttl, found := c.TTL("k")
+	if !found {
+		t.Fatal("TTL(k) = not found; want found")
+	}
+	if ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("TTL(k) = %v; want in (0, 1m]", ttl)
+	}
+
+	if _, found := c.TTL("missing"); found {
+		t.Fatal("TTL(missing) = found; want not found")
+	}
+}
+
+func TestSaveLoadRoundTripsSlidingEntry(t *testing.T) {
+	c := NewSowhyCache(WithJanitorInterval(time.Hour))
+	defer c.Close()
+
+	c.SetSliding("session", "payload", 50*time.Millisecond)
+
+	var buf bytes.Buffer
+	if err := c.Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded := NewSowhyCache(WithJanitorInterval(time.Hour))
+	defer loaded.Close()
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if _, found := loaded.Get("session"); !found {
+		t.Fatal("Get(session) = not found immediately after Load")
+	}
+
+	// The idle window must have survived the round trip: waiting past it
+	// without touching the key should expire it, not leave it permanent.
+	time.Sleep(100 * time.Millisecond)
+	if _, found := loaded.Get("session"); found {
+		t.Fatal("sliding entry survived past its idle window after Save/Load; Sliding/Idle/LastAccess did not round-trip")
+	}
+}